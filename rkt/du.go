@@ -0,0 +1,166 @@
+// Copyright 2016 The rkt Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//+build linux
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/coreos/rkt/pkg/fileutil"
+	"github.com/coreos/rkt/store/imagestore"
+	"github.com/coreos/rkt/store/treestore"
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cmdDU = &cobra.Command{
+		Use:   "du [--json]",
+		Short: "Report disk usage of images, pods and garbage",
+		Long: `Attributes disk usage to images in the local store (ACI blobs plus
+rendered trees), to pods in all lifecycle directories, and to garbage
+awaiting collection.
+
+The reported garbage size is an estimate of what "rkt gc" would reclaim,
+the unused image size an estimate of what "rkt image gc" would reclaim.`,
+		Run: runWrapper(runDU),
+	}
+	flagDUJSON bool
+)
+
+func init() {
+	cmdRkt.AddCommand(cmdDU)
+	cmdDU.Flags().BoolVar(&flagDUJSON, "json", false, "print the disk usage report in JSON format")
+}
+
+// duImage is the disk usage of a single image in the store.
+type duImage struct {
+	ID            string `json:"id"`
+	Name          string `json:"name"`
+	Size          int64  `json:"size"`
+	TreeStoreSize int64  `json:"tree_store_size"`
+}
+
+// duPod is the disk usage of a single pod directory. For running overlay
+// pods this is dominated by the overlay upper directories, volumes and
+// journals, since the rendered trees are accounted to their images.
+type duPod struct {
+	UUID  string `json:"uuid"`
+	State string `json:"state"`
+	Size  int64  `json:"size"`
+}
+
+// duReport is the complete disk usage report.
+type duReport struct {
+	Images []duImage `json:"images"`
+	Pods   []duPod   `json:"pods"`
+
+	ImagesSize  int64 `json:"images_size"`
+	PodsSize    int64 `json:"pods_size"`
+	GarbageSize int64 `json:"garbage_size"`
+}
+
+func runDU(cmd *cobra.Command, args []string) (exit int) {
+	s, err := imagestore.NewStore(storeDir())
+	if err != nil {
+		stderr.PrintE("cannot open store", err)
+		return 1
+	}
+
+	ts, err := treestore.NewStore(treeStoreDir(), s)
+	if err != nil {
+		stderr.PrintE("cannot open treestore", err)
+		return 1
+	}
+
+	report, err := gatherDU(s, ts)
+	if err != nil {
+		stderr.PrintE("cannot gather disk usage", err)
+		return 1
+	}
+
+	if flagDUJSON {
+		enc := json.NewEncoder(os.Stdout)
+		if err := enc.Encode(report); err != nil {
+			stderr.PrintE("error encoding JSON", err)
+			return 1
+		}
+		return 0
+	}
+
+	printDU(report)
+	return 0
+}
+
+// gatherDU walks the image store, the treestore and all pod directories
+// and attributes their sizes.
+func gatherDU(s *imagestore.Store, ts *treestore.Store) (*duReport, error) {
+	report := &duReport{}
+
+	aciInfos, err := s.GetAllACIInfos(nil, false)
+	if err != nil {
+		return nil, err
+	}
+	for _, ai := range aciInfos {
+		report.Images = append(report.Images, duImage{
+			ID:            ai.BlobKey,
+			Name:          ai.Name,
+			Size:          ai.Size,
+			TreeStoreSize: ai.TreeStoreSize,
+		})
+		report.ImagesSize += ai.Size + ai.TreeStoreSize
+	}
+
+	if err := walkPods(includeAllDirs, func(p *pod) {
+		size, err := fileutil.DirSize(p.path())
+		if err != nil {
+			stderr.PrintE(fmt.Sprintf("cannot size pod %q", p.uuid), err)
+			return
+		}
+		report.Pods = append(report.Pods, duPod{
+			UUID:  p.uuid.String(),
+			State: p.getState(),
+			Size:  size,
+		})
+		if p.isGarbage || p.isExitedGarbage {
+			report.GarbageSize += size
+		} else {
+			report.PodsSize += size
+		}
+	}); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+func printDU(report *duReport) {
+	defer tabOut.Flush()
+	fmt.Fprintf(tabOut, "IMAGE ID\tNAME\tSIZE\tTREE STORE SIZE\n")
+	for _, di := range report.Images {
+		fmt.Fprintf(tabOut, "%s\t%s\t%s\t%s\n", di.ID, di.Name,
+			humanize.IBytes(uint64(di.Size)), humanize.IBytes(uint64(di.TreeStoreSize)))
+	}
+	fmt.Fprintf(tabOut, "\nPOD UUID\tSTATE\tSIZE\n")
+	for _, dp := range report.Pods {
+		fmt.Fprintf(tabOut, "%s\t%s\t%s\n", dp.UUID, dp.State, humanize.IBytes(uint64(dp.Size)))
+	}
+	fmt.Fprintf(tabOut, "\nTOTAL IMAGES\t%s\n", humanize.IBytes(uint64(report.ImagesSize)))
+	fmt.Fprintf(tabOut, "TOTAL PODS\t%s\n", humanize.IBytes(uint64(report.PodsSize)))
+	fmt.Fprintf(tabOut, "TOTAL GARBAGE\t%s\n", humanize.IBytes(uint64(report.GarbageSize)))
+}
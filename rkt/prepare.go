@@ -59,6 +59,7 @@ func init() {
 	cmdPrepare.Flags().BoolVar(&flagQuiet, "quiet", false, "suppress superfluous output on stdout, print only the UUID on success")
 	cmdPrepare.Flags().BoolVar(&flagInheritEnv, "inherit-env", false, "inherit all environment variables not set by apps")
 	cmdPrepare.Flags().BoolVar(&flagNoOverlay, "no-overlay", false, "disable overlay filesystem")
+	cmdPrepare.Flags().BoolVar(&flagFullTreeStoreCheck, "full-tree-store-check", false, "force a full tree store verification instead of the incremental one")
 	cmdPrepare.Flags().BoolVar(&flagPrivateUsers, "private-users", false, "run within user namespaces.")
 	cmdPrepare.Flags().Var(&flagExplicitEnv, "set-env", "environment variable to set for apps in the form name=value")
 	cmdPrepare.Flags().Var(&flagEnvFromFile, "set-env-file", "the path to an environment variables file")
@@ -198,6 +199,7 @@ func runPrepare(cmd *cobra.Command, args []string) (exit int) {
 		UseOverlay:         !flagNoOverlay && ovlOk,
 		PrivateUsers:       privateUsers,
 		SkipTreeStoreCheck: globalFlags.InsecureFlags.SkipOnDiskCheck(),
+		FullTreeStoreCheck: flagFullTreeStoreCheck,
 	}
 
 	if len(flagPodManifest) > 0 {
@@ -55,23 +55,24 @@ will instead be appended to the preceding image app's exec arguments. End the
 image arguments with a lone "---" to resume argument parsing.`,
 		Run: ensureSuperuser(runWrapper(runRun)),
 	}
-	flagPorts        portList
-	flagNet          common.NetList
-	flagPrivateUsers bool
-	flagInheritEnv   bool
-	flagExplicitEnv  envMap
-	flagEnvFromFile  envFileMap
-	flagInteractive  bool
-	flagDNS          flagStringList
-	flagDNSSearch    flagStringList
-	flagDNSOpt       flagStringList
-	flagNoOverlay    bool
-	flagStoreOnly    bool
-	flagNoStore      bool
-	flagPodManifest  string
-	flagMDSRegister  bool
-	flagUUIDFileSave string
-	flagHostname     string
+	flagPorts              portList
+	flagNet                common.NetList
+	flagPrivateUsers       bool
+	flagInheritEnv         bool
+	flagExplicitEnv        envMap
+	flagEnvFromFile        envFileMap
+	flagInteractive        bool
+	flagDNS                flagStringList
+	flagDNSSearch          flagStringList
+	flagDNSOpt             flagStringList
+	flagNoOverlay          bool
+	flagFullTreeStoreCheck bool
+	flagStoreOnly          bool
+	flagNoStore            bool
+	flagPodManifest        string
+	flagMDSRegister        bool
+	flagUUIDFileSave       string
+	flagHostname           string
 )
 
 func init() {
@@ -83,6 +84,7 @@ func init() {
 	cmdRun.Flags().Lookup("net").NoOptDefVal = "default"
 	cmdRun.Flags().BoolVar(&flagInheritEnv, "inherit-env", false, "inherit all environment variables not set by apps")
 	cmdRun.Flags().BoolVar(&flagNoOverlay, "no-overlay", false, "disable overlay filesystem")
+	cmdRun.Flags().BoolVar(&flagFullTreeStoreCheck, "full-tree-store-check", false, "force a full tree store verification instead of the incremental one")
 	cmdRun.Flags().BoolVar(&flagPrivateUsers, "private-users", false, "run within user namespaces.")
 	cmdRun.Flags().Var(&flagExplicitEnv, "set-env", "environment variable to set for apps in the form name=value")
 	cmdRun.Flags().Var(&flagEnvFromFile, "set-env-file", "path to an environment variables file")
@@ -275,6 +277,7 @@ func runRun(cmd *cobra.Command, args []string) (exit int) {
 		UseOverlay:         useOverlay,
 		PrivateUsers:       privateUsers,
 		SkipTreeStoreCheck: globalFlags.InsecureFlags.SkipOnDiskCheck(),
+		FullTreeStoreCheck: flagFullTreeStoreCheck,
 	}
 
 	if len(flagPodManifest) > 0 {
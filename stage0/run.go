@@ -67,6 +67,7 @@ type PrepareConfig struct {
 	Ports              []types.ExposedPort // list of ports that rkt will expose on the host
 	UseOverlay         bool                // prepare pod with overlay fs
 	SkipTreeStoreCheck bool                // skip checking the treestore before rendering
+	FullTreeStoreCheck bool                // force a full tree hash verification instead of the incremental one
 	PodManifest        string              // use the pod manifest specified by the user, this will ignore flags such as '--volume', '--port', etc.
 	PrivateUsers       *user.UidRange      // User namespaces
 }
@@ -622,7 +623,7 @@ func prepareAppImage(cfg PrepareConfig, appName types.ACName, img types.Hash, cd
 		}
 
 		if !cfg.SkipTreeStoreCheck {
-			hash, err := cfg.TreeStore.Check(treeStoreID)
+			hash, err := checkTreeStore(cfg, treeStoreID)
 			if err != nil {
 				log.PrintE("warning: tree cache is in a bad state.  Rebuilding...", err)
 				var err error
@@ -688,6 +689,16 @@ func setupAppImage(cfg RunConfig, appName types.ACName, img types.Hash, cdir str
 	return nil
 }
 
+// checkTreeStore verifies the rendered tree identified by treeStoreID,
+// using the cheaper incremental verification unless a full one is forced
+// by the configuration.
+func checkTreeStore(cfg PrepareConfig, treeStoreID string) (string, error) {
+	if cfg.FullTreeStoreCheck {
+		return cfg.TreeStore.Check(treeStoreID)
+	}
+	return cfg.TreeStore.CheckIncremental(treeStoreID)
+}
+
 // prepareStage1Image renders and verifies tree cache of the given hash
 // when using overlay.
 // When useOverlay is false, it attempts to render and expand the stage1.
@@ -703,7 +714,7 @@ func prepareStage1Image(cfg PrepareConfig, img types.Hash, cdir string, useOverl
 	}
 
 	if !cfg.SkipTreeStoreCheck {
-		hash, err := cfg.TreeStore.Check(treeStoreID)
+		hash, err := checkTreeStore(cfg, treeStoreID)
 		if err != nil {
 			log.Printf("warning: tree cache is in a bad state: %v. Rebuilding...", err)
 			var err error
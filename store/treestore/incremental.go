@@ -0,0 +1,184 @@
+// Copyright 2016 The rkt Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package treestore
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	specaci "github.com/appc/spec/aci"
+	"github.com/coreos/rkt/pkg/lock"
+	"github.com/hashicorp/errwrap"
+)
+
+const (
+	checkinfofilename = "checkinfo"
+
+	// fullCheckPeriod is how long the result of a full tree hash
+	// verification is trusted by incremental verification before a full
+	// verification is forced again.
+	fullCheckPeriod = 24 * time.Hour
+)
+
+// fileStamp holds the cheap-to-collect attributes of a rendered file used
+// by incremental verification instead of rehashing the file contents.
+type fileStamp struct {
+	Size    int64 `json:"size"`
+	ModTime int64 `json:"modtime"`
+	Mode    int64 `json:"mode"`
+}
+
+// checkInfo caches the per-file state of a rendered tree at the time of
+// its last successful full verification.
+type checkInfo struct {
+	LastFullCheck time.Time            `json:"lastFullCheck"`
+	Files         map[string]fileStamp `json:"files"`
+}
+
+func (ts *Store) checkInfoPath(id string) string {
+	return filepath.Join(ts.GetPath(id), checkinfofilename)
+}
+
+// collectStamps walks the rendered tree gathering a fileStamp for every
+// entry, skipping the treestore metadata files like buildWalker does.
+func (ts *Store) collectStamps(id string) (map[string]fileStamp, error) {
+	treepath := ts.GetPath(id)
+	stamps := map[string]fileStamp{}
+	err := filepath.Walk(treepath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		relpath, err := filepath.Rel(treepath, path)
+		if err != nil {
+			return err
+		}
+		if relpath == "." ||
+			relpath == specaci.ManifestFile ||
+			relpath == hashfilename ||
+			relpath == renderedfilename ||
+			relpath == imagefilename ||
+			relpath == checkinfofilename {
+			return nil
+		}
+		stamps[relpath] = fileStamp{
+			Size:    info.Size(),
+			ModTime: info.ModTime().UnixNano(),
+			Mode:    int64(info.Mode()),
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errwrap.Wrap(errors.New("error walking rootfs"), err)
+	}
+	return stamps, nil
+}
+
+// writeCheckInfo records the current file stamps of the rendered tree and
+// the time of the last full verification.
+func (ts *Store) writeCheckInfo(id string) error {
+	stamps, err := ts.collectStamps(id)
+	if err != nil {
+		return err
+	}
+	ci := &checkInfo{
+		LastFullCheck: time.Now(),
+		Files:         stamps,
+	}
+	cij, err := json.Marshal(ci)
+	if err != nil {
+		return errwrap.Wrap(errors.New("cannot marshal check info"), err)
+	}
+	if err := ioutil.WriteFile(ts.checkInfoPath(id), cij, 0644); err != nil {
+		return errwrap.Wrap(errors.New("cannot write check info file"), err)
+	}
+	return nil
+}
+
+// readCheckInfo loads the cached check info for the given id. It returns
+// an error also when the file is missing, leaving the decision on whether
+// to fall back to a full verification to the caller.
+func (ts *Store) readCheckInfo(id string) (*checkInfo, error) {
+	cij, err := ioutil.ReadFile(ts.checkInfoPath(id))
+	if err != nil {
+		return nil, err
+	}
+	ci := &checkInfo{}
+	if err := json.Unmarshal(cij, ci); err != nil {
+		return nil, errwrap.Wrap(errors.New("cannot unmarshal check info"), err)
+	}
+	return ci, nil
+}
+
+// checkIncremental compares the current file stamps of the rendered tree
+// against the cached ones. A mismatch doesn't necessarily mean the tree is
+// corrupted, so no error is returned, just a report that a full
+// verification is needed.
+func (ts *Store) checkIncremental(id string, ci *checkInfo) (bool, error) {
+	stamps, err := ts.collectStamps(id)
+	if err != nil {
+		return false, err
+	}
+	if len(stamps) != len(ci.Files) {
+		return false, nil
+	}
+	for relpath, stamp := range stamps {
+		if ci.Files[relpath] != stamp {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// CheckIncremental verifies the treestore consistency for the specified id
+// like Check, but trusts per-file mtime/size/mode stamps collected at the
+// last full verification instead of rehashing all the contents. A full
+// verification is performed when no stamps are cached, when the stamps
+// don't match or when the last full verification is older than
+// fullCheckPeriod, and its result is cached for the next calls.
+func (ts *Store) CheckIncremental(id string) (string, error) {
+	treeStoreKeyLock, err := lock.SharedKeyLock(ts.lockDir, id)
+	if err != nil {
+		return "", errwrap.Wrap(errors.New("error locking tree store"), err)
+	}
+	defer treeStoreKeyLock.Close()
+
+	ci, err := ts.readCheckInfo(id)
+	if err == nil && time.Since(ci.LastFullCheck) < fullCheckPeriod {
+		ok, err := ts.checkIncremental(id, ci)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			hash, err := ioutil.ReadFile(filepath.Join(ts.GetPath(id), hashfilename))
+			if err != nil {
+				return "", errwrap.Wrap(errors.New("cannot read hash file"), err)
+			}
+			return string(hash), nil
+		}
+	}
+
+	hash, err := ts.check(id)
+	if err != nil {
+		return "", err
+	}
+	if err := ts.writeCheckInfo(id); err != nil {
+		return "", err
+	}
+	return hash, nil
+}
@@ -468,7 +468,8 @@ func buildWalker(root string, aw specaci.ArchiveWriter) filepath.WalkFunc {
 		if relpath == specaci.ManifestFile ||
 			relpath == hashfilename ||
 			relpath == renderedfilename ||
-			relpath == imagefilename {
+			relpath == imagefilename ||
+			relpath == checkinfofilename {
 			// ignore; this will be written by the archive writer
 			// TODO(jonboulle): does this make sense? maybe just remove from archivewriter?
 			return nil